@@ -40,6 +40,18 @@ const (
 	TLSTransport = "tcp-tls"
 	// QUICTransport represents DNS over QUIC.
 	QUICTransport = "quic"
+	// GRPCTransport represents DNS over gRPC (DoG).
+	GRPCTransport = "grpc"
+
+	// RoundRobinStrategy makes each worker rotate through Benchmark.Servers, one server per query. This is the default strategy.
+	RoundRobinStrategy = "round-robin"
+	// RandomStrategy makes each worker pick a random server from Benchmark.Servers for every query.
+	RandomStrategy = "random"
+	// StickyWorkerStrategy pins each worker to a single server from Benchmark.Servers for its entire lifetime,
+	// which is useful for isolating per-server latency.
+	StickyWorkerStrategy = "sticky-worker"
+	// ParallelStrategy fans out every question to all Benchmark.Servers, so their responses can be compared.
+	ParallelStrategy = "parallel"
 
 	// GetHTTPMethod represents GET HTTP Method for DoH.
 	GetHTTPMethod = "get"
@@ -91,8 +103,24 @@ type Benchmark struct {
 	// For DoT the format is <IP/host>[:port], if port is not provided then port 853 is used.
 	// For DoH the format is https://<IP/host>[:port][/path] or http://<IP/host>[:port][/path], if port is not provided then either 443 or 80 port is used. If no path is provided, then /dns-query is used.
 	// For DoQ the format is quic://<IP/host>[:port], if port is not provided then port 853 is used.
+	// For DoG (DNS-over-gRPC) the format is grpc://<IP/host>[:port], if port is not provided then port 443 is used.
+	// For DNSCrypt the format is either a DNS Stamp (sdns://...) or an explicit
+	// dnscrypt://<hex pubkey>@<IP/host>[:port]?provider=<name>, if port is not provided then port 443 is used.
+	//
+	// Mutually exclusive with Benchmark.Servers, use Server for the common single-target case.
 	Server string
 
+	// Servers represents a pool of target servers to benchmark at once, each in the same format as Benchmark.Server.
+	// Different servers in the pool may use different transports, e.g. one plain udp and one https:// target. How a
+	// query is routed to one (or all) of the servers is controlled by Benchmark.ServerStrategy. Mutually exclusive
+	// with Benchmark.Server.
+	Servers []string
+
+	// ServerStrategy controls how queries are distributed across Benchmark.Servers when it contains more than one
+	// server. Supported values are RoundRobinStrategy (default), RandomStrategy, StickyWorkerStrategy and
+	// ParallelStrategy.
+	ServerStrategy string
+
 	// Types is an array of DNS query types, that should be used in benchmark. All domains retrieved from domain data source will be fired with each
 	// type specified here.
 	Types []string
@@ -184,7 +212,8 @@ type Benchmark struct {
 	// DohProtocol controls HTTP protocol version used fo sending DoH requests. Supported values are "1.1", "2" and "3". Default is "1.1".
 	DohProtocol string
 
-	// Insecure disables server TLS certificate validation. Applicable for DoT, DoH and DoQ.
+	// Insecure disables server TLS certificate validation. Applicable for DoT, DoH, DoQ and DoG. For DoG, setting
+	// this to true also switches the gRPC transport from TLS to plaintext.
 	Insecure bool
 
 	// ProgressBar controls whether the progress bar is printed.
@@ -215,30 +244,84 @@ type Benchmark struct {
 	// PrometheusMetricsAddr configures address for Prometheus metrics endpoint.
 	PrometheusMetricsAddr string
 
+	// CacheProbe enables a resolver cache-behavior benchmarking mode. When enabled, each worker
+	// sends every question twice (or more): a "cold" query followed by one or more "warm"
+	// queries, so that the effectiveness of the benchmarked resolver's cache can be measured.
+	// A cache hit is detected heuristically, by the returned TTL on a warm query being strictly
+	// lower than the TTL returned for the cold query.
+	CacheProbe bool
+	// CacheProbeGap configures the gap between the "cold" query and the following "warm" queries
+	// fired by Benchmark.CacheProbe. When 0, the warm queries are fired back-to-back with the cold one.
+	CacheProbeGap time.Duration
+	// CacheFlood configures how many duplicate "warm" queries are fired per unique question when
+	// Benchmark.CacheProbe is enabled, to amplify cache-hit measurement. Defaults to 1.
+	CacheFlood int
+
+	// PTR controls whether Benchmark.Queries entries that parse as an IP address or CIDR range
+	// are converted into reverse DNS (PTR) questions in the in-addr.arpa/ip6.arpa zone, instead of
+	// being treated as domain names. The query type for such questions is always PTR, regardless
+	// of Benchmark.Types. This also applies to IP addresses read from data source files.
+	PTR bool
+
+	// ECS configures a fixed EDNS Client Subnet (RFC 7871) option, accepting a CIDR such as
+	// "192.0.2.0/24" or "2001:db8::/32", which is appended to every query. Mutually exclusive
+	// with Benchmark.ECSRandom and Benchmark.EdnsOpt. Coexists with Benchmark.DNSSEC, sharing
+	// the same OPT record. ECS is emitted even when Benchmark.Edns0 is 0, in which case an OPT
+	// record with DefaultEdns0BufferSize is created to carry it.
+	ECS string
+	// ECSRandom configures a CIDR range, such as "10.0.0.0/8", from which a random /24 (or /64 for
+	// IPv6) subnet is picked and sent as the EDNS Client Subnet option on every query, so that
+	// ECS-aware GeoDNS resolvers can be benchmarked more realistically. Mutually exclusive with
+	// Benchmark.ECS and Benchmark.EdnsOpt.
+	ECSRandom string
+
 	// internal variable so we do not have to parse the address with each request.
 	useDoH            bool
 	useQuic           bool
+	useGRPC           bool
+	useDNSCrypt       bool
+	dnscrypt          *dnscryptServer
+	ecsSubnet         *net.IPNet
+	ecsRandomSubnet   *net.IPNet
+	resolvedServers   []*Benchmark
 	requestDelayStart time.Duration
 	requestDelayEnd   time.Duration
 }
 
 type queryFunc func(context.Context, *dns.Msg) (*dns.Msg, error)
 
-// init validates and normalizes Benchmark settings.
-func (b *Benchmark) init() error {
-	if b.Writer == nil {
-		b.Writer = os.Stdout
-	}
-
-	if len(b.Server) == 0 {
-		return errors.New("server for benchmarking must not be empty")
-	}
-
+// resolveServer detects the transport scheme of b.Server, strips it, fills in the internal
+// transport flags and applies the transport's default port. It is used both for the legacy single
+// Benchmark.Server case and, independently, for each entry of Benchmark.Servers, since different
+// servers in the same run may use different transports.
+func (b *Benchmark) resolveServer() error {
 	b.useDoH, _ = isHTTPUrl(b.Server)
 	b.useQuic = strings.HasPrefix(b.Server, "quic://")
 	if b.useQuic {
 		b.Server = strings.TrimPrefix(b.Server, "quic://")
 	}
+	b.useGRPC = strings.HasPrefix(b.Server, GRPCTransportPrefix)
+	if b.useGRPC {
+		b.Server = strings.TrimPrefix(b.Server, GRPCTransportPrefix)
+	}
+
+	if strings.HasPrefix(b.Server, "sdns://") || strings.HasPrefix(b.Server, "dnscrypt://") {
+		var (
+			dnscrypt *dnscryptServer
+			err      error
+		)
+		if strings.HasPrefix(b.Server, "sdns://") {
+			dnscrypt, err = parseDNSCryptStamp(b.Server)
+		} else {
+			dnscrypt, err = parseDNSCryptURL(b.Server)
+		}
+		if err != nil {
+			return err
+		}
+		b.useDNSCrypt = true
+		b.dnscrypt = dnscrypt
+		b.Server = dnscrypt.addr
+	}
 
 	if b.useDoH {
 		parsedURL, err := url.Parse(b.Server)
@@ -251,6 +334,54 @@ func (b *Benchmark) init() error {
 	}
 
 	b.addPortIfMissing()
+	return nil
+}
+
+// init validates and normalizes Benchmark settings.
+func (b *Benchmark) init() error {
+	if b.Writer == nil {
+		b.Writer = os.Stdout
+	}
+
+	if len(b.Server) == 0 && len(b.Servers) == 0 {
+		return errors.New("server for benchmarking must not be empty")
+	}
+	if len(b.Server) > 0 && len(b.Servers) > 0 {
+		return errors.New("--server and --servers is specified at once, only one can be used")
+	}
+	if len(b.Servers) == 0 {
+		b.Servers = []string{b.Server}
+	}
+
+	switch b.ServerStrategy {
+	case "":
+		b.ServerStrategy = RoundRobinStrategy
+	case RoundRobinStrategy, RandomStrategy, StickyWorkerStrategy, ParallelStrategy:
+	default:
+		return fmt.Errorf("'%s' is not a supported server strategy", b.ServerStrategy)
+	}
+
+	for _, server := range b.Servers {
+		if len(server) == 0 {
+			return errors.New("server for benchmarking must not be empty")
+		}
+		serverBenchmark := *b
+		serverBenchmark.Server = server
+		serverBenchmark.Servers = nil
+		if err := serverBenchmark.resolveServer(); err != nil {
+			return fmt.Errorf("server '%s': %w", server, err)
+		}
+		b.resolvedServers = append(b.resolvedServers, &serverBenchmark)
+	}
+	// mirror the first resolved server onto the top-level Benchmark fields, kept for backward
+	// compatibility with the single-server reporting/logging paths.
+	first := b.resolvedServers[0]
+	b.Server = first.Server
+	b.useDoH = first.useDoH
+	b.useQuic = first.useQuic
+	b.useGRPC = first.useGRPC
+	b.useDNSCrypt = first.useDNSCrypt
+	b.dnscrypt = first.dnscrypt
 
 	if b.Count == 0 && b.Duration == 0 {
 		b.Count = 1
@@ -283,6 +414,34 @@ func (b *Benchmark) init() error {
 		}
 	}
 
+	if len(b.ECS) > 0 && len(b.ECSRandom) > 0 {
+		return errors.New("--ecs and --ecs-random is specified at once, only one can be used")
+	}
+	if (len(b.ECS) > 0 || len(b.ECSRandom) > 0) && len(b.EdnsOpt) > 0 {
+		return errors.New("--ecs/--ecs-random cannot be combined with --ednsopt")
+	}
+	if len(b.ECS) > 0 {
+		_, subnet, err := net.ParseCIDR(b.ECS)
+		if err != nil {
+			return fmt.Errorf("--ecs is not a valid CIDR: %w", err)
+		}
+		b.ecsSubnet = subnet
+	}
+	if len(b.ECSRandom) > 0 {
+		_, subnet, err := net.ParseCIDR(b.ECSRandom)
+		if err != nil {
+			return fmt.Errorf("--ecs-random is not a valid CIDR: %w", err)
+		}
+		b.ecsRandomSubnet = subnet
+	}
+
+	if b.CacheFlood < 0 {
+		return errors.New("--cache-flood must not be negative")
+	}
+	if b.CacheFlood > 0 && !b.CacheProbe {
+		return errors.New("--cache-flood can only be used together with --cache-probe")
+	}
+
 	if b.RequestLogEnabled && len(b.RequestLogPath) == 0 {
 		b.RequestLogPath = DefaultRequestLogPath
 	}
@@ -345,7 +504,10 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 		qTypes = append(qTypes, dns.StringToType[v])
 	}
 
-	queryFactory := workerQueryFactory(b)
+	serverQueryFactories := make([]func() queryFunc, len(b.resolvedServers))
+	for i, rs := range b.resolvedServers {
+		serverQueryFactories[i] = workerQueryFactory(rs)
+	}
 
 	limits := ""
 	var limit ratelimit.Limiter
@@ -363,9 +525,19 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 	}
 
 	if !b.Silent && !b.JSON {
-		network := b.network()
-		printutils.NeutralFprintf(b.Writer, "Benchmarking %s via %s with %s concurrent requests %s\n",
-			printutils.HighlightSprint(b.Server), printutils.HighlightSprint(network), printutils.HighlightSprint(b.Concurrency), limits)
+		if len(b.resolvedServers) == 1 {
+			network := b.network()
+			printutils.NeutralFprintf(b.Writer, "Benchmarking %s via %s with %s concurrent requests %s\n",
+				printutils.HighlightSprint(b.Server), printutils.HighlightSprint(network), printutils.HighlightSprint(b.Concurrency), limits)
+		} else {
+			var targets []string
+			for _, rs := range b.resolvedServers {
+				targets = append(targets, fmt.Sprintf("%s (%s)", rs.Server, rs.network()))
+			}
+			printutils.NeutralFprintf(b.Writer, "Benchmarking %s targets [%s] using the %s strategy with %s concurrent requests %s\n",
+				printutils.HighlightSprint(len(b.resolvedServers)), printutils.HighlightSprint(strings.Join(targets, ", ")),
+				printutils.HighlightSprint(b.ServerStrategy), printutils.HighlightSprint(b.Concurrency), limits)
+		}
 	}
 
 	var bar *progressbar.ProgressBar
@@ -396,16 +568,21 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 		}()
 	}
 
-	stats := make([]*ResultStats, b.Concurrency)
+	numServers := len(b.resolvedServers)
+	stats := make([]*ResultStats, int(b.Concurrency)*numServers)
 
 	var wg sync.WaitGroup
 	var w uint32
 	for w = 0; w < b.Concurrency; w++ {
-		st := newResultStats(b)
-		stats[w] = st
+		serverStats := make([]*ResultStats, numServers)
+		for si, rs := range b.resolvedServers {
+			st := newResultStats(rs, rs.Server)
+			serverStats[si] = st
+			stats[int(w)*numServers+si] = st
+		}
 
 		wg.Add(1)
-		go func(workerID uint32, st *ResultStats) {
+		go func(workerID uint32, serverStats []*ResultStats) {
 			defer func() {
 				wg.Done()
 			}()
@@ -419,11 +596,19 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 				workerLimit = ratelimit.New(b.RateLimitWorker)
 			}
 
-			query := queryFactory()
+			queries := make([]queryFunc, numServers)
+			for i, f := range serverQueryFactories {
+				queries[i] = f()
+			}
+			var serverCounter uint32
 
 			for i := int64(0); i < b.Count || b.Duration != 0; i++ {
 				for _, q := range questions {
-					for _, qt := range qTypes {
+					qtypes := qTypes
+					if q.forcedQtype != 0 {
+						qtypes = []uint16{q.forcedQtype}
+					}
+					for _, qt := range qtypes {
 						if ctx.Err() != nil {
 							return
 						}
@@ -445,16 +630,9 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 						req.RecursionDesired = b.Recurse
 
 						req.Question = make([]dns.Question, 1)
-						question := dns.Question{Name: q, Qtype: qt, Qclass: dns.ClassINET}
+						question := dns.Question{Name: q.name, Qtype: qt, Qclass: dns.ClassINET}
 						req.Question[0] = question
 
-						if b.useQuic {
-							req.Id = 0
-						} else {
-							// nolint:gosec
-							req.Id = uint16(rand.Intn(1 << 16))
-						}
-
 						if b.Edns0 > 0 {
 							req.SetEdns0(b.Edns0, false)
 						}
@@ -469,22 +647,29 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 							}
 							edns0.SetDo(true)
 						}
-
-						start := time.Now()
-
-						reqTimeoutCtx, cancel := context.WithTimeout(ctx, b.RequestTimeout)
-						resp, err := query(reqTimeoutCtx, &req)
-						cancel()
-						if deadline, deadlineSet := reqTimeoutCtx.Deadline(); err != nil && deadlineSet && start.After(deadline) {
-							// Benchmark was cancelled before sending request, do not count this query results and end the worker
-							return
+						switch {
+						case b.ecsSubnet != nil:
+							addECSOpt(&req, b.ecsSubnet)
+						case b.ecsRandomSubnet != nil:
+							addECSOpt(&req, randomECSSubnet(b.ecsRandomSubnet, rando))
 						}
-						dur := time.Since(start)
-						if b.RequestLogEnabled {
-							logRequest(workerID, req, resp, err, dur)
+
+						if b.ServerStrategy == ParallelStrategy {
+							aborted := false
+							for si, rs := range b.resolvedServers {
+								if !b.executeQuery(ctx, rs, queries[si], req, serverStats[si], workerID) {
+									aborted = true
+								}
+							}
+							if aborted {
+								return
+							}
+						} else {
+							si := b.pickServerIndex(workerID, &serverCounter, rando)
+							if !b.executeQuery(ctx, b.resolvedServers[si], queries[si], req, serverStats[si], workerID) {
+								return
+							}
 						}
-						st.record(&req, resp, err, start, dur)
-						b.measureProm(req, resp, dur, err)
 
 						if incrementBar {
 							bar.Add(1)
@@ -494,7 +679,7 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 					}
 				}
 			}
-		}(w, st)
+		}(w, serverStats)
 	}
 
 	wg.Wait()
@@ -505,6 +690,129 @@ func (b *Benchmark) Run(ctx context.Context) ([]*ResultStats, error) {
 	return stats, nil
 }
 
+// pickServerIndex selects which entry of b.resolvedServers should receive the next query for the
+// given worker, according to b.ServerStrategy. It is not used for ParallelStrategy, which fans out
+// to every server instead of picking one. counter is a per-worker cursor used by RoundRobinStrategy.
+func (b *Benchmark) pickServerIndex(workerID uint32, counter *uint32, rando *rand.Rand) int {
+	switch b.ServerStrategy {
+	case RandomStrategy:
+		return rando.Intn(len(b.resolvedServers))
+	case StickyWorkerStrategy:
+		return int(workerID) % len(b.resolvedServers)
+	default: // RoundRobinStrategy
+		idx := int(*counter) % len(b.resolvedServers)
+		*counter++
+		return idx
+	}
+}
+
+// executeQuery sends req (or, when rs.CacheProbe is enabled, the cold/warm sequence derived from
+// it) to the single resolved target server rs and records the outcome into st. It returns false if
+// the worker should stop.
+func (b *Benchmark) executeQuery(ctx context.Context, rs *Benchmark, query queryFunc, req dns.Msg, st *ResultStats, workerID uint32) bool {
+	if rs.useQuic {
+		req.Id = 0
+	} else {
+		// nolint:gosec
+		req.Id = uint16(rand.Intn(1 << 16))
+	}
+
+	if rs.CacheProbe {
+		return rs.runCacheProbe(ctx, query, &req, st, workerID)
+	}
+	_, _, ok := rs.sendQuery(ctx, query, &req, st, workerID)
+	return ok
+}
+
+// sendQuery sends a single DNS query using query, recording the result into st and returns the
+// response, the measured request duration and whether the worker should keep running (false when
+// ctx was cancelled before the request could be sent).
+func (b *Benchmark) sendQuery(ctx context.Context, query queryFunc, req *dns.Msg, st *ResultStats, workerID uint32) (*dns.Msg, time.Duration, bool) {
+	start := time.Now()
+
+	reqTimeoutCtx, cancel := context.WithTimeout(ctx, b.RequestTimeout)
+	resp, err := query(reqTimeoutCtx, req)
+	cancel()
+	if deadline, deadlineSet := reqTimeoutCtx.Deadline(); err != nil && deadlineSet && start.After(deadline) {
+		// Benchmark was cancelled before sending request, do not count this query results and end the worker
+		return nil, 0, false
+	}
+	dur := time.Since(start)
+	if b.RequestLogEnabled {
+		logRequest(workerID, *req, resp, err, dur)
+	}
+	st.record(req, resp, err, start, dur)
+	b.measureProm(*req, resp, dur, err)
+
+	return resp, dur, true
+}
+
+// runCacheProbe implements Benchmark.CacheProbe: it sends a "cold" query followed by one or more
+// "warm" queries for the same question, detects cache hits heuristically via a decrease in the
+// returned TTL and records the outcome into st and the cache Prometheus metrics. It returns false
+// if the worker should stop.
+func (b *Benchmark) runCacheProbe(ctx context.Context, query queryFunc, req *dns.Msg, st *ResultStats, workerID uint32) bool {
+	coldStart := time.Now()
+	coldResp, coldDur, ok := b.sendQuery(ctx, query, req, st, workerID)
+	if !ok {
+		return false
+	}
+	coldTTL, hasColdTTL := firstAnswerTTL(coldResp)
+	st.recordCacheProbe(false, false, coldStart, coldDur)
+
+	warmRepeats := b.CacheFlood
+	if warmRepeats <= 0 {
+		warmRepeats = 1
+	}
+
+	for i := 0; i < warmRepeats; i++ {
+		if b.CacheProbeGap > 0 {
+			waitFor(ctx, b.CacheProbeGap)
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		warmReq := *req
+		// nolint:gosec
+		warmReq.Id = uint16(rand.Intn(1 << 16))
+
+		warmStart := time.Now()
+		warmResp, warmDur, ok := b.sendQuery(ctx, query, &warmReq, st, workerID)
+		if !ok {
+			return false
+		}
+
+		hit := false
+		if warmTTL, hasWarmTTL := firstAnswerTTL(warmResp); hasColdTTL && hasWarmTTL && warmTTL < coldTTL {
+			hit = true
+		}
+		st.recordCacheProbe(true, hit, warmStart, warmDur)
+		b.measureCacheProm(hit)
+	}
+	return true
+}
+
+// firstAnswerTTL returns the TTL of the first answer record of m, used to heuristically detect
+// resolver cache hits on repeated Benchmark.CacheProbe queries.
+func firstAnswerTTL(m *dns.Msg) (uint32, bool) {
+	if m == nil || len(m.Answer) == 0 {
+		return 0, false
+	}
+	return m.Answer[0].Header().Ttl, true
+}
+
+func (b *Benchmark) measureCacheProm(hit bool) {
+	if len(b.PrometheusMetricsAddr) == 0 {
+		return
+	}
+	if hit {
+		dnsCacheHitsMetrics.WithLabelValues(b.Server).Inc()
+		return
+	}
+	dnsCacheMissesMetrics.WithLabelValues(b.Server).Inc()
+}
+
 func (b *Benchmark) measureProm(req dns.Msg, resp *dns.Msg, time time.Duration, err error) {
 	if len(b.PrometheusMetricsAddr) == 0 {
 		return
@@ -512,13 +820,13 @@ func (b *Benchmark) measureProm(req dns.Msg, resp *dns.Msg, time time.Duration,
 	if resp != nil {
 		rcode := dns.RcodeToString[resp.Rcode]
 		respType := dns.TypeToString[resp.Question[0].Qtype]
-		dnsResponseTotalMetrics.WithLabelValues(respType, rcode).Inc()
+		dnsResponseTotalMetrics.WithLabelValues(respType, rcode, b.Server).Inc()
 	}
 	if err != nil {
-		errorsTotalMetrics.WithLabelValues().Inc()
+		errorsTotalMetrics.WithLabelValues(b.Server).Inc()
 	}
 	reqType := dns.TypeToString[req.Question[0].Qtype]
-	dnsRequestsDurationMetrics.WithLabelValues(reqType).Observe(time.Seconds())
+	dnsRequestsDurationMetrics.WithLabelValues(reqType, b.Server).Observe(time.Seconds())
 }
 
 func (b *Benchmark) delay(ctx context.Context, rando *rand.Rand) {
@@ -576,6 +884,14 @@ func (b *Benchmark) network() string {
 		return QUICTransport
 	}
 
+	if b.useGRPC {
+		return GRPCTransport
+	}
+
+	if b.useDNSCrypt {
+		return DNSCryptTransport
+	}
+
 	network := UDPTransport
 	if b.TCP {
 		network = TCPTransport
@@ -598,6 +914,56 @@ func addEdnsOpt(m *dns.Msg, ednsOpt string) {
 	o.Option = append(o.Option, &dns.EDNS0_LOCAL{Code: uint16(code), Data: data})
 }
 
+// addECSOpt appends a properly-encoded EDNS Client Subnet (RFC 7871) option for subnet to m,
+// creating an OPT record with DefaultEdns0BufferSize if one is not already present.
+func addECSOpt(m *dns.Msg, subnet *net.IPNet) {
+	o := m.IsEdns0()
+	if o == nil {
+		m.SetEdns0(DefaultEdns0BufferSize, false)
+		o = m.IsEdns0()
+	}
+
+	ones, bits := subnet.Mask.Size()
+	family := uint16(1)
+	addr := subnet.IP.To4()
+	if bits == 128 {
+		family = 2
+		addr = subnet.IP.To16()
+	}
+
+	o.Option = append(o.Option, &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		Family:        family,
+		SourceNetmask: uint8(ones),
+		SourceScope:   0,
+		Address:       addr,
+	})
+}
+
+// randomECSSubnet picks a random /24 (or /64 for IPv6) subnet contained within base, used for
+// Benchmark.ECSRandom so each query can carry a different, but still plausible, client subnet.
+func randomECSSubnet(base *net.IPNet, rando *rand.Rand) *net.IPNet {
+	baseOnes, bits := base.Mask.Size()
+	targetOnes := 24
+	if bits == 128 {
+		targetOnes = 64
+	}
+	if targetOnes < baseOnes {
+		targetOnes = baseOnes
+	}
+
+	ip := make(net.IP, len(base.IP))
+	copy(ip, base.IP)
+
+	for bit := baseOnes; bit < targetOnes; bit++ {
+		if rando.Intn(2) == 1 {
+			ip[bit/8] |= 1 << uint(7-bit%8)
+		}
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(targetOnes, bits)}
+}
+
 func (b *Benchmark) addPortIfMissing() {
 	if b.useDoH {
 		// both HTTPS and HTTP are using default ports 443 and 80 if no other port is specified
@@ -614,6 +980,15 @@ func (b *Benchmark) addPortIfMissing() {
 			b.Server = net.JoinHostPort(b.Server, "853")
 			return
 		}
+		if b.useGRPC {
+			b.Server = net.JoinHostPort(b.Server, "443")
+			return
+		}
+		if b.useDNSCrypt {
+			b.Server = net.JoinHostPort(b.Server, "443")
+			b.dnscrypt.addr = b.Server
+			return
+		}
 		b.Server = net.JoinHostPort(b.Server, "53")
 		return
 	}
@@ -629,8 +1004,16 @@ func isHTTPUrl(s string) (ok bool, network string) {
 	return false, ""
 }
 
-func (b *Benchmark) prepareQuestions() ([]string, error) {
-	var questions []string
+// preparedQuestion represents a single question derived from Benchmark.Queries. forcedQtype is
+// non-zero when the question must be fired with that query type regardless of Benchmark.Types,
+// which is the case for the PTR questions generated when Benchmark.PTR is enabled.
+type preparedQuestion struct {
+	name        string
+	forcedQtype uint16
+}
+
+func (b *Benchmark) prepareQuestions() ([]preparedQuestion, error) {
+	var questions []preparedQuestion
 	for _, q := range b.Queries {
 		if ok, _ := isHTTPUrl(q); ok {
 			resp, err := client.Get(q)
@@ -642,15 +1025,83 @@ func (b *Benchmark) prepareQuestions() ([]string, error) {
 			}
 			scanner := bufio.NewScanner(resp.Body)
 			for scanner.Scan() {
-				questions = append(questions, dns.Fqdn(scanner.Text()))
+				qs, err := b.prepareQuestionEntry(scanner.Text())
+				if err != nil {
+					return nil, err
+				}
+				questions = append(questions, qs...)
 			}
 		} else {
-			questions = append(questions, dns.Fqdn(q))
+			qs, err := b.prepareQuestionEntry(q)
+			if err != nil {
+				return nil, err
+			}
+			questions = append(questions, qs...)
 		}
 	}
 	return questions, nil
 }
 
+// prepareQuestionEntry converts a single line from a Benchmark.Queries data source into one or
+// more preparedQuestion. When Benchmark.PTR is enabled and entry parses as an IP address or CIDR
+// range, it is expanded into the corresponding in-addr.arpa/ip6.arpa PTR question(s) instead of
+// being treated as a domain name.
+func (b *Benchmark) prepareQuestionEntry(entry string) ([]preparedQuestion, error) {
+	if b.PTR {
+		if questions, ok, err := ptrQuestionsForEntry(entry); ok {
+			return questions, err
+		}
+	}
+	return []preparedQuestion{{name: dns.Fqdn(entry)}}, nil
+}
+
+// maxPTRCIDRExpansion caps how many host addresses a single CIDR entry is expanded into when
+// Benchmark.PTR is enabled, to guard against accidentally generating millions of questions.
+const maxPTRCIDRExpansion = 65536
+
+// ptrQuestionsForEntry recognizes entry as either a single IP address or a CIDR range and, if it
+// matches, returns the PTR question(s) it expands to. ok is false when entry is neither, in which
+// case it should be treated as a regular domain name.
+func ptrQuestionsForEntry(entry string) (questions []preparedQuestion, ok bool, err error) {
+	if ip := net.ParseIP(entry); ip != nil {
+		name, err := dns.ReverseAddr(ip.String())
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to generate PTR query for '%s': %w", entry, err)
+		}
+		return []preparedQuestion{{name: name, forcedQtype: dns.TypePTR}}, true, nil
+	}
+
+	ip, ipNet, err := net.ParseCIDR(entry)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	for current := ip.Mask(ipNet.Mask); ipNet.Contains(current); current = nextIP(current) {
+		if len(questions) >= maxPTRCIDRExpansion {
+			return nil, true, fmt.Errorf("CIDR '%s' expands to more than %d addresses, which is not supported", entry, maxPTRCIDRExpansion)
+		}
+		name, err := dns.ReverseAddr(current.String())
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to generate PTR query for '%s': %w", current, err)
+		}
+		questions = append(questions, preparedQuestion{name: name, forcedQtype: dns.TypePTR})
+	}
+	return questions, true, nil
+}
+
+// nextIP returns the IP address following ip, used to enumerate a CIDR range host by host.
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
 func checkLimit(ctx context.Context, limiter ratelimit.Limiter) error {
 	done := make(chan struct{})
 	go func() {
@@ -0,0 +1,78 @@
+package dnsbench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// ttlSequenceQuery returns a queryFunc that replies to each successive query with the next TTL in
+// ttls, cycling back to the first once exhausted, used to drive Benchmark.runCacheProbe's TTL-based
+// cache hit detection deterministically.
+func ttlSequenceQuery(ttls ...uint32) queryFunc {
+	calls := 0
+	return func(_ context.Context, req *dns.Msg) (*dns.Msg, error) {
+		ttl := ttls[calls%len(ttls)]
+		calls++
+
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+		})
+		return resp, nil
+	}
+}
+
+func TestRunCacheProbeDetectsHitOnDecreasedTTL(t *testing.T) {
+	b := &Benchmark{RequestTimeout: time.Second}
+	st := newResultStats(b, "")
+	req := new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+
+	ok := b.runCacheProbe(context.Background(), ttlSequenceQuery(60, 55), req, st, 0)
+	if !ok {
+		t.Fatal("runCacheProbe returned false, want true")
+	}
+
+	if got := st.Counters.CacheColdQueries; got != 1 {
+		t.Errorf("CacheColdQueries = %d, want 1", got)
+	}
+	if got := st.Counters.CacheWarmHits; got != 1 {
+		t.Errorf("CacheWarmHits = %d, want 1 (warm TTL 55 < cold TTL 60)", got)
+	}
+}
+
+func TestRunCacheProbeNoHitOnUnchangedTTL(t *testing.T) {
+	b := &Benchmark{RequestTimeout: time.Second}
+	st := newResultStats(b, "")
+	req := new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+
+	ok := b.runCacheProbe(context.Background(), ttlSequenceQuery(60, 60), req, st, 0)
+	if !ok {
+		t.Fatal("runCacheProbe returned false, want true")
+	}
+
+	if got := st.Counters.CacheWarmHits; got != 0 {
+		t.Errorf("CacheWarmHits = %d, want 0 (warm TTL 60 is not less than cold TTL 60)", got)
+	}
+}
+
+func TestRunCacheProbeHonorsCacheFlood(t *testing.T) {
+	b := &Benchmark{RequestTimeout: time.Second, CacheFlood: 3}
+	st := newResultStats(b, "")
+	req := new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+
+	ok := b.runCacheProbe(context.Background(), ttlSequenceQuery(60, 55), req, st, 0)
+	if !ok {
+		t.Fatal("runCacheProbe returned false, want true")
+	}
+
+	if got := len(st.CacheWarmTimings); got != b.CacheFlood {
+		t.Errorf("got %d warm datapoints, want CacheFlood=%d", got, b.CacheFlood)
+	}
+}
@@ -0,0 +1,476 @@
+package dnsbench
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// DNSCryptTransport represents DNSCrypt v2.
+const DNSCryptTransport = "dnscrypt"
+
+const (
+	dnscryptCertMagic      = "DNSC"
+	dnscryptMinQueryLen    = 256
+	dnscryptQueryPadBlock  = 64
+	dnscryptHalfNonceLen   = 12
+	dnscryptClientMagicLen = 8
+	dnscryptSignatureLen   = 64
+
+	// esVersionXSalsa20Poly1305 identifies the original, XSalsa20-Poly1305 (NaCl box) construction.
+	esVersionXSalsa20Poly1305 = 0x0001
+	// esVersionXChacha20Poly1305 identifies the XChaCha20-Poly1305 construction.
+	esVersionXChacha20Poly1305 = 0x0002
+)
+
+// dnscryptServer holds the parsed identity of a DNSCrypt v2 resolver, as obtained either from a
+// DNS Stamp or from an explicit dnscrypt:// server definition.
+type dnscryptServer struct {
+	addr         string
+	providerName string
+	providerKey  [32]byte
+}
+
+// dnscryptCert is a verified DNSCrypt certificate, as published by the resolver in a TXT record
+// under its provider name.
+type dnscryptCert struct {
+	esVersion   uint16
+	clientMagic [8]byte
+	resolverPK  [32]byte
+	serial      uint32
+	tsBegin     uint32
+	tsEnd       uint32
+}
+
+func (c *dnscryptCert) valid(now time.Time) bool {
+	ts := uint32(now.Unix())
+	return ts >= c.tsBegin && ts <= c.tsEnd
+}
+
+// parseDNSCryptStamp parses a DNS Stamp (sdns://...) as specified at https://dnscrypt.info/stamps-specifications,
+// returning the resolver address, provider name and provider public key it encodes.
+func parseDNSCryptStamp(stamp string) (*dnscryptServer, error) {
+	stamp = strings.TrimPrefix(stamp, "sdns://")
+	raw, err := base64.RawURLEncoding.DecodeString(stamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS stamp: %w", err)
+	}
+	if len(raw) < 1 || raw[0] != 0x01 {
+		return nil, errors.New("invalid DNS stamp: not a DNSCrypt (protocol 0x01) stamp")
+	}
+	raw = raw[1:]
+	if len(raw) < 8 {
+		return nil, errors.New("invalid DNS stamp: missing properties")
+	}
+	raw = raw[8:] // properties bitflags, not used for benchmarking purposes
+
+	addr, raw, err := readLengthPrefixed(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS stamp address: %w", err)
+	}
+	pk, raw, err := readLengthPrefixed(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS stamp public key: %w", err)
+	}
+	if len(pk) != 32 {
+		return nil, fmt.Errorf("invalid DNS stamp public key length: expected 32 bytes, got %d", len(pk))
+	}
+	providerName, _, err := readLengthPrefixed(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS stamp provider name: %w", err)
+	}
+
+	s := &dnscryptServer{addr: string(addr), providerName: dns.Fqdn(string(providerName))}
+	copy(s.providerKey[:], pk)
+	return s, nil
+}
+
+func readLengthPrefixed(b []byte) (value, rest []byte, err error) {
+	if len(b) < 1 {
+		return nil, nil, errors.New("unexpected end of data")
+	}
+	n := int(b[0])
+	b = b[1:]
+	if len(b) < n {
+		return nil, nil, errors.New("unexpected end of data")
+	}
+	return b[:n], b[n:], nil
+}
+
+// parseDNSCryptURL parses an explicit dnscrypt://<pubkey>@<host>:<port>?provider=<name> server
+// definition, where <pubkey> is the resolver provider public key encoded as hexadecimal.
+func parseDNSCryptURL(server string) (*dnscryptServer, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dnscrypt:// server: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("invalid dnscrypt:// server: missing public key")
+	}
+	pk, err := hexDecodeKey(u.User.Username())
+	if err != nil {
+		return nil, fmt.Errorf("invalid dnscrypt:// public key: %w", err)
+	}
+	providerName := u.Query().Get("provider")
+	if providerName == "" {
+		return nil, errors.New("invalid dnscrypt:// server: missing ?provider=<name>")
+	}
+
+	s := &dnscryptServer{addr: u.Host, providerName: dns.Fqdn(providerName), providerKey: pk}
+	return s, nil
+}
+
+func hexDecodeKey(s string) ([32]byte, error) {
+	var key [32]byte
+	decoded := make([]byte, 32)
+	if _, err := fmt.Sscanf(s, "%x", &decoded); err != nil {
+		return key, err
+	}
+	copy(key[:], decoded)
+	return key, nil
+}
+
+// fetchDNSCryptCert queries the resolver's provider name for its current DNSCrypt certificate bundle,
+// verifies the Ed25519 signature of each candidate certificate against the provider public key and
+// returns the certificate that is presently valid.
+func fetchDNSCryptCert(ctx context.Context, s *dnscryptServer) (*dnscryptCert, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(s.providerName, dns.TypeTXT)
+
+	req, err := m.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.Dial("udp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, dns.MaxMsgSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(dns.Msg)
+	if err := resp.Unpack(buf[:n]); err != nil {
+		return nil, err
+	}
+
+	var best *dnscryptCert
+	now := time.Now()
+	for _, rr := range resp.Answer {
+		txt, ok := rr.(*dns.TXT)
+		if !ok {
+			continue
+		}
+		cert, err := parseDNSCryptCert(strings.Join(txt.Txt, ""), s.providerKey)
+		if err != nil {
+			continue
+		}
+		if !cert.valid(now) {
+			continue
+		}
+		if best == nil || cert.serial > best.serial {
+			best = cert
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no valid DNSCrypt certificate found for provider %q", s.providerName)
+	}
+	return best, nil
+}
+
+// parseDNSCryptCert decodes and verifies a single DNSCrypt certificate, as described at
+// https://dnscrypt.info/protocol/, signed with providerKey. The certificate has the wire layout
+// cert-magic(4) es-version(2) protocol-minor-version(2) signature(64) resolver-pk(32) client-magic(8)
+// serial(4) ts-start(4) ts-end(4), where the signature covers everything following it.
+func parseDNSCryptCert(raw string, providerKey [32]byte) (*dnscryptCert, error) {
+	b := []byte(raw)
+	if len(b) < 4+2+2+dnscryptSignatureLen+32+dnscryptClientMagicLen+4+4+4 || string(b[:4]) != dnscryptCertMagic {
+		return nil, errors.New("not a DNSCrypt certificate")
+	}
+	esVersion := binary.BigEndian.Uint16(b[4:6])
+	if esVersion != esVersionXSalsa20Poly1305 && esVersion != esVersionXChacha20Poly1305 {
+		return nil, fmt.Errorf("unsupported DNSCrypt es-version %#04x", esVersion)
+	}
+	// b[6:8] is the protocol-minor-version, which is always 0x00 0x00 at present and is not used here.
+	b = b[8:]
+
+	signature := b[:dnscryptSignatureLen]
+	signed := b[dnscryptSignatureLen:]
+	if !ed25519.Verify(providerKey[:], signed, signature) {
+		return nil, errors.New("invalid DNSCrypt certificate signature")
+	}
+
+	cert := &dnscryptCert{esVersion: esVersion}
+	copy(cert.resolverPK[:], signed[:32])
+	signed = signed[32:]
+	copy(cert.clientMagic[:], signed[:dnscryptClientMagicLen])
+	signed = signed[dnscryptClientMagicLen:]
+	cert.serial = binary.BigEndian.Uint32(signed[:4])
+	signed = signed[4:]
+	cert.tsBegin = binary.BigEndian.Uint32(signed[:4])
+	signed = signed[4:]
+	cert.tsEnd = binary.BigEndian.Uint32(signed[:4])
+
+	return cert, nil
+}
+
+// dnscryptSession caches a negotiated certificate and the corresponding client keypair for a
+// single worker connection, so that a new certificate is only fetched once it expires or
+// Benchmark.QperConn forces a reconnect.
+type dnscryptSession struct {
+	mu         sync.Mutex
+	server     *dnscryptServer
+	cert       *dnscryptCert
+	publicKey  [32]byte
+	privateKey [32]byte
+	queries    int64
+	qPerConn   int64
+}
+
+func newDNSCryptSession(server *dnscryptServer, qPerConn int64) *dnscryptSession {
+	return &dnscryptSession{server: server, qPerConn: qPerConn}
+}
+
+func (s *dnscryptSession) ensureCert(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	needsRekey := s.cert == nil || !s.cert.valid(time.Now())
+	if s.qPerConn > 0 && s.queries >= s.qPerConn {
+		needsRekey = true
+	}
+	if !needsRekey {
+		return nil
+	}
+
+	cert, err := fetchDNSCryptCert(ctx, s.server)
+	if err != nil {
+		return err
+	}
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	s.cert = cert
+	s.publicKey = *pub
+	s.privateKey = *priv
+	s.queries = 0
+	return nil
+}
+
+// query encrypts req according to the DNSCrypt v2 protocol, sends it to the resolver over UDP
+// (falling back to TCP when the response is truncated) and returns the decrypted response.
+func (s *dnscryptSession) query(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	if err := s.ensureCert(ctx); err != nil {
+		return nil, err
+	}
+
+	packed, err := req.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	cert := s.cert
+	pub := s.publicKey
+	priv := s.privateKey
+	s.mu.Unlock()
+
+	var clientNonce [24]byte
+	if _, err := rand.Read(clientNonce[:dnscryptHalfNonceLen]); err != nil {
+		return nil, err
+	}
+
+	padded := padQuery(packed)
+	sharedKey, err := sharedKeyFor(cert.esVersion, &cert.resolverPK, &priv)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := seal(cert.esVersion, padded, &clientNonce, sharedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	packet := make([]byte, 0, dnscryptClientMagicLen+32+dnscryptHalfNonceLen+len(encrypted))
+	packet = append(packet, cert.clientMagic[:]...)
+	packet = append(packet, pub[:]...)
+	packet = append(packet, clientNonce[:dnscryptHalfNonceLen]...)
+	packet = append(packet, encrypted...)
+
+	respBytes, err := s.send(ctx, packet)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(respBytes) < dnscryptClientMagicLen+24 {
+		return nil, errors.New("DNSCrypt response too short")
+	}
+	resolverNonce := respBytes[dnscryptClientMagicLen : dnscryptClientMagicLen+24]
+	var serverNonce [24]byte
+	copy(serverNonce[:], resolverNonce)
+	ciphertext := respBytes[dnscryptClientMagicLen+24:]
+
+	decrypted, err := open(cert.esVersion, ciphertext, &serverNonce, sharedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt DNSCrypt response: %w", err)
+	}
+
+	resp := new(dns.Msg)
+	if err := resp.Unpack(decrypted); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.queries++
+	s.mu.Unlock()
+	return resp, nil
+}
+
+func (s *dnscryptSession) send(ctx context.Context, packet []byte) ([]byte, error) {
+	conn, err := net.Dial("udp", s.server.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(packet); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, dns.MaxMsgSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(dns.Msg)
+	if err := msg.Unpack(buf[:n]); err == nil && msg.Truncated {
+		return s.sendTCP(ctx, packet)
+	}
+	return buf[:n], nil
+}
+
+func (s *dnscryptSession) sendTCP(ctx context.Context, packet []byte) ([]byte, error) {
+	conn, err := net.Dial("tcp", s.server.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	var lenPrefix [2]byte
+	binary.BigEndian.PutUint16(lenPrefix[:], uint16(len(packet)))
+	if _, err := conn.Write(append(lenPrefix[:], packet...)); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Read(lenPrefix[:]); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, binary.BigEndian.Uint16(lenPrefix[:]))
+	if _, err := conn.Read(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// sharedKeyFor derives the shared key between pub and priv for the cipher negotiated by esVersion.
+// XSalsa20-Poly1305 additionally runs the X25519 scalar multiplication result through HSalsa20, as
+// box.Precompute does, while XChaCha20-Poly1305 uses the raw X25519 output directly as its key.
+func sharedKeyFor(esVersion uint16, pub, priv *[32]byte) ([]byte, error) {
+	if esVersion == esVersionXChacha20Poly1305 {
+		return curve25519.X25519(priv[:], pub[:])
+	}
+	var shared [32]byte
+	box.Precompute(&shared, pub, priv)
+	return shared[:], nil
+}
+
+// seal encrypts plaintext with the nonce and sharedKey, using the cipher negotiated by esVersion.
+func seal(esVersion uint16, plaintext []byte, nonce *[24]byte, sharedKey []byte) ([]byte, error) {
+	if esVersion == esVersionXChacha20Poly1305 {
+		aead, err := chacha20poly1305.NewX(sharedKey)
+		if err != nil {
+			return nil, err
+		}
+		return aead.Seal(nil, nonce[:], plaintext, nil), nil
+	}
+	var key [32]byte
+	copy(key[:], sharedKey)
+	return box.SealAfterPrecomputation(nil, plaintext, nonce, &key), nil
+}
+
+// open decrypts ciphertext with the nonce and sharedKey, using the cipher negotiated by esVersion.
+func open(esVersion uint16, ciphertext []byte, nonce *[24]byte, sharedKey []byte) ([]byte, error) {
+	if esVersion == esVersionXChacha20Poly1305 {
+		aead, err := chacha20poly1305.NewX(sharedKey)
+		if err != nil {
+			return nil, err
+		}
+		return aead.Open(nil, nonce[:], ciphertext, nil)
+	}
+	var key [32]byte
+	copy(key[:], sharedKey)
+	decrypted, ok := box.OpenAfterPrecomputation(nil, ciphertext, nonce, &key)
+	if !ok {
+		return nil, errors.New("failed to decrypt DNSCrypt response")
+	}
+	return decrypted, nil
+}
+
+// padQuery pads a DNS query with the DNSCrypt padding scheme (0x80 followed by zero bytes) up to
+// the next multiple of dnscryptQueryPadBlock, with a minimum length of dnscryptMinQueryLen.
+func padQuery(msg []byte) []byte {
+	padded := append([]byte{}, msg...)
+	padded = append(padded, 0x80)
+	target := dnscryptMinQueryLen
+	for target < len(padded) {
+		target += dnscryptQueryPadBlock
+	}
+	for len(padded) < target {
+		padded = append(padded, 0x00)
+	}
+	return padded
+}
+
+// dnscryptQueryFunc builds a queryFunc that benchmarks a DNSCrypt v2 resolver, reusing a single
+// session (negotiated certificate and client keypair) across all queries of the worker, unless
+// Benchmark.SeparateWorkerConnections is set, in which case a fresh session is used for every query.
+func dnscryptQueryFunc(b *Benchmark, server *dnscryptServer) queryFunc {
+	shared := newDNSCryptSession(server, b.QperConn)
+	return func(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+		var session *dnscryptSession
+		if b.SeparateWorkerConnections {
+			session = newDNSCryptSession(server, b.QperConn)
+		} else {
+			session = shared
+		}
+		return session.query(ctx, req)
+	}
+}
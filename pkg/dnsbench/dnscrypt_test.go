@@ -0,0 +1,121 @@
+package dnsbench
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// buildCert assembles a raw DNSCrypt certificate with the given es-version, signed with providerKey,
+// wrapping the same resolver-pk/client-magic/serial/ts-start/ts-end values that parseDNSCryptCert
+// should recover.
+func buildCert(t *testing.T, providerKey ed25519.PrivateKey, esVersion uint16, resolverPK [32]byte, clientMagic [8]byte, serial, tsBegin, tsEnd uint32) string {
+	t.Helper()
+
+	signed := make([]byte, 0, 32+8+4+4+4)
+	signed = append(signed, resolverPK[:]...)
+	signed = append(signed, clientMagic[:]...)
+	signed = binary.BigEndian.AppendUint32(signed, serial)
+	signed = binary.BigEndian.AppendUint32(signed, tsBegin)
+	signed = binary.BigEndian.AppendUint32(signed, tsEnd)
+
+	signature := ed25519.Sign(providerKey, signed)
+
+	cert := make([]byte, 0, 4+2+2+len(signature)+len(signed))
+	cert = append(cert, dnscryptCertMagic...)
+	cert = binary.BigEndian.AppendUint16(cert, esVersion)
+	cert = binary.BigEndian.AppendUint16(cert, 0) // protocol-minor-version
+	cert = append(cert, signature...)
+	cert = append(cert, signed...)
+	return string(cert)
+}
+
+func TestParseDNSCryptCertRoundTrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate provider key: %v", err)
+	}
+	var providerKey [32]byte
+	copy(providerKey[:], pub)
+
+	var resolverPK [32]byte
+	copy(resolverPK[:], "01234567890123456789012345678901")
+	var clientMagic [8]byte
+	copy(clientMagic[:], "DNSC")
+
+	now := uint32(time.Now().Unix())
+
+	tests := []struct {
+		name      string
+		esVersion uint16
+	}{
+		{"XSalsa20Poly1305", esVersionXSalsa20Poly1305},
+		{"XChacha20Poly1305", esVersionXChacha20Poly1305},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := buildCert(t, priv, tt.esVersion, resolverPK, clientMagic, 42, now-10, now+3600)
+
+			cert, err := parseDNSCryptCert(raw, providerKey)
+			if err != nil {
+				t.Fatalf("parseDNSCryptCert failed: %v", err)
+			}
+			if cert.esVersion != tt.esVersion {
+				t.Errorf("esVersion = %#04x, want %#04x", cert.esVersion, tt.esVersion)
+			}
+			if cert.resolverPK != resolverPK {
+				t.Errorf("resolverPK = %x, want %x", cert.resolverPK, resolverPK)
+			}
+			if cert.clientMagic != clientMagic {
+				t.Errorf("clientMagic = %x, want %x", cert.clientMagic, clientMagic)
+			}
+			if cert.serial != 42 {
+				t.Errorf("serial = %d, want 42", cert.serial)
+			}
+			if !cert.valid(time.Now()) {
+				t.Error("cert.valid() = false, want true")
+			}
+		})
+	}
+}
+
+func TestParseDNSCryptCertRejectsBadSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate provider key: %v", err)
+	}
+	var providerKey [32]byte
+	copy(providerKey[:], pub)
+
+	var resolverPK [32]byte
+	var clientMagic [8]byte
+	now := uint32(time.Now().Unix())
+	raw := []byte(buildCert(t, priv, esVersionXSalsa20Poly1305, resolverPK, clientMagic, 1, now-10, now+3600))
+
+	// flip a byte inside the signed portion so the signature no longer matches.
+	raw[len(raw)-1] ^= 0xff
+
+	if _, err := parseDNSCryptCert(string(raw), providerKey); err == nil {
+		t.Error("parseDNSCryptCert accepted a certificate with a tampered signed portion")
+	}
+}
+
+func TestParseDNSCryptCertRejectsUnsupportedEsVersion(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate provider key: %v", err)
+	}
+	var providerKey [32]byte
+	copy(providerKey[:], pub)
+
+	var resolverPK [32]byte
+	var clientMagic [8]byte
+	now := uint32(time.Now().Unix())
+	raw := buildCert(t, priv, 0x00ff, resolverPK, clientMagic, 1, now-10, now+3600)
+
+	if _, err := parseDNSCryptCert(raw, providerKey); err == nil {
+		t.Error("parseDNSCryptCert accepted an unsupported es-version")
+	}
+}
@@ -0,0 +1,98 @@
+package dnsbench
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestAddECSOptIPv4(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("192.0.2.0/24")
+	if err != nil {
+		t.Fatalf("ParseCIDR failed: %v", err)
+	}
+
+	m := new(dns.Msg)
+	addECSOpt(m, subnet)
+
+	o := m.IsEdns0()
+	if o == nil {
+		t.Fatal("addECSOpt did not attach an OPT record")
+	}
+	if len(o.Option) != 1 {
+		t.Fatalf("got %d EDNS0 options, want 1", len(o.Option))
+	}
+	ecs, ok := o.Option[0].(*dns.EDNS0_SUBNET)
+	if !ok {
+		t.Fatalf("option is %T, want *dns.EDNS0_SUBNET", o.Option[0])
+	}
+	if ecs.Family != 1 {
+		t.Errorf("Family = %d, want 1 (IPv4)", ecs.Family)
+	}
+	if ecs.SourceNetmask != 24 {
+		t.Errorf("SourceNetmask = %d, want 24", ecs.SourceNetmask)
+	}
+	if !ecs.Address.Equal(net.ParseIP("192.0.2.0")) {
+		t.Errorf("Address = %s, want 192.0.2.0", ecs.Address)
+	}
+}
+
+func TestAddECSOptIPv6(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("2001:db8::/64")
+	if err != nil {
+		t.Fatalf("ParseCIDR failed: %v", err)
+	}
+
+	m := new(dns.Msg)
+	addECSOpt(m, subnet)
+
+	o := m.IsEdns0()
+	ecs, ok := o.Option[0].(*dns.EDNS0_SUBNET)
+	if !ok {
+		t.Fatalf("option is %T, want *dns.EDNS0_SUBNET", o.Option[0])
+	}
+	if ecs.Family != 2 {
+		t.Errorf("Family = %d, want 2 (IPv6)", ecs.Family)
+	}
+	if ecs.SourceNetmask != 64 {
+		t.Errorf("SourceNetmask = %d, want 64", ecs.SourceNetmask)
+	}
+}
+
+func TestRandomECSSubnetStaysWithinBase(t *testing.T) {
+	_, base, err := net.ParseCIDR("192.0.2.0/20")
+	if err != nil {
+		t.Fatalf("ParseCIDR failed: %v", err)
+	}
+	rando := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		got := randomECSSubnet(base, rando)
+		ones, bits := got.Mask.Size()
+		if ones != 24 || bits != 32 {
+			t.Fatalf("mask = /%d (bits %d), want /24 (bits 32)", ones, bits)
+		}
+		if !base.Contains(got.IP) {
+			t.Fatalf("randomECSSubnet produced %s, not contained in base %s", got.IP, base)
+		}
+	}
+}
+
+func TestRandomECSSubnetNarrowBaseUnchanged(t *testing.T) {
+	_, base, err := net.ParseCIDR("192.0.2.0/28")
+	if err != nil {
+		t.Fatalf("ParseCIDR failed: %v", err)
+	}
+	rando := rand.New(rand.NewSource(1))
+
+	got := randomECSSubnet(base, rando)
+	ones, _ := got.Mask.Size()
+	if ones != 28 {
+		t.Errorf("mask = /%d, want /28 (base mask should not be widened)", ones)
+	}
+	if !got.IP.Equal(base.IP) {
+		t.Errorf("IP = %s, want %s", got.IP, base.IP)
+	}
+}
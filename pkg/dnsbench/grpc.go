@@ -0,0 +1,69 @@
+package dnsbench
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/miekg/dns"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/tantalor93/dnspyre/v3/pkg/dnsbench/grpcpb"
+)
+
+// GRPCTransportPrefix is the URL scheme used to select the DNS-over-gRPC (DoG) transport.
+const GRPCTransportPrefix = "grpc://"
+
+// newGRPCConn dials a gRPC connection to b.Server, using TLS unless b.Insecure is set, in which
+// case a plaintext connection is established instead.
+func newGRPCConn(b *Benchmark) (*grpc.ClientConn, error) {
+	creds := credentials.NewTLS(&tls.Config{InsecureSkipVerify: b.Insecure}) //nolint:gosec
+	if b.Insecure {
+		creds = insecure.NewCredentials()
+	}
+	return grpc.NewClient(b.Server, grpc.WithTransportCredentials(creds))
+}
+
+// grpcQueryFunc builds a queryFunc that sends DNS queries over DNS-over-gRPC (DoG), reusing a
+// single grpc.ClientConn per worker unless Benchmark.SeparateWorkerConnections forces a fresh
+// connection for every query.
+func grpcQueryFunc(b *Benchmark) (queryFunc, error) {
+	var sharedConn *grpc.ClientConn
+	if !b.SeparateWorkerConnections {
+		conn, err := newGRPCConn(b)
+		if err != nil {
+			return nil, err
+		}
+		sharedConn = conn
+	}
+
+	return func(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+		conn := sharedConn
+		if conn == nil {
+			c, err := newGRPCConn(b)
+			if err != nil {
+				return nil, err
+			}
+			defer c.Close()
+			conn = c
+		}
+
+		packed, err := req.Pack()
+		if err != nil {
+			return nil, err
+		}
+
+		client := grpcpb.NewDNSServiceClient(conn)
+		reply, err := client.Query(ctx, &grpcpb.DNSMessage{Data: packed})
+		if err != nil {
+			return nil, err
+		}
+
+		resp := new(dns.Msg)
+		if err := resp.Unpack(reply.Data); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}, nil
+}
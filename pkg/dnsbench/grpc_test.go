@@ -0,0 +1,78 @@
+package dnsbench
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"google.golang.org/grpc"
+
+	"github.com/tantalor93/dnspyre/v3/pkg/dnsbench/grpcpb"
+)
+
+type echoDNSServiceServer struct {
+	grpcpb.UnimplementedDNSServiceServer
+}
+
+func (echoDNSServiceServer) Query(_ context.Context, in *grpcpb.DNSMessage) (*grpcpb.DNSMessage, error) {
+	req := new(dns.Msg)
+	if err := req.Unpack(in.Data); err != nil {
+		return nil, err
+	}
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   net.ParseIP("192.0.2.1"),
+	})
+
+	packed, err := resp.Pack()
+	if err != nil {
+		return nil, err
+	}
+	return &grpcpb.DNSMessage{Data: packed}, nil
+}
+
+func TestGRPCQueryFuncRoundTrip(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer lis.Close()
+
+	srv := grpc.NewServer()
+	grpcpb.RegisterDNSServiceServer(srv, echoDNSServiceServer{})
+	go srv.Serve(lis) //nolint:errcheck
+	defer srv.Stop()
+
+	b := &Benchmark{Server: lis.Addr().String(), Insecure: true}
+
+	query, err := grpcQueryFunc(b)
+	if err != nil {
+		t.Fatalf("grpcQueryFunc returned error: %v", err)
+	}
+
+	req := new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := query(ctx, req)
+	if err != nil {
+		t.Fatalf("query returned error: %v", err)
+	}
+	if len(resp.Answer) != 1 {
+		t.Fatalf("got %d answers, want 1", len(resp.Answer))
+	}
+	a, ok := resp.Answer[0].(*dns.A)
+	if !ok {
+		t.Fatalf("answer is %T, want *dns.A", resp.Answer[0])
+	}
+	if a.A.String() != "192.0.2.1" {
+		t.Errorf("answer A = %s, want 192.0.2.1", a.A)
+	}
+}
@@ -0,0 +1,41 @@
+package dnsbench
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// dnsResponseTotalMetrics counts, per server, the responses received, labeled by query type and
+	// response code.
+	dnsResponseTotalMetrics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dns_responses_total",
+		Help: "Number of DNS responses received.",
+	}, []string{"responsetype", "rcode", "server"})
+
+	// errorsTotalMetrics counts, per server, the requests for which no response was received.
+	errorsTotalMetrics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dns_errors_total",
+		Help: "Number of DNS requests which resulted in an error.",
+	}, []string{"server"})
+
+	// dnsRequestsDurationMetrics observes, per server, the duration of DNS requests, labeled by query type.
+	dnsRequestsDurationMetrics = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "dns_requests_duration_seconds",
+		Help: "Duration of DNS requests.",
+	}, []string{"querytype", "server"})
+
+	// dnsCacheHitsMetrics counts, per server, the warm Benchmark.CacheProbe queries that were
+	// heuristically detected as resolver cache hits.
+	dnsCacheHitsMetrics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dns_cache_hits_total",
+		Help: "Number of Benchmark.CacheProbe warm queries detected as resolver cache hits.",
+	}, []string{"server"})
+
+	// dnsCacheMissesMetrics counts, per server, the warm Benchmark.CacheProbe queries that were not
+	// detected as resolver cache hits.
+	dnsCacheMissesMetrics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dns_cache_misses_total",
+		Help: "Number of Benchmark.CacheProbe warm queries not detected as resolver cache hits.",
+	}, []string{"server"})
+)
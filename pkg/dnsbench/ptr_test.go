@@ -0,0 +1,87 @@
+package dnsbench
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestPtrQuestionsForEntrySingleIP(t *testing.T) {
+	questions, ok, err := ptrQuestionsForEntry("192.0.2.1")
+	if err != nil {
+		t.Fatalf("ptrQuestionsForEntry returned error: %v", err)
+	}
+	if !ok {
+		t.Fatal("ptrQuestionsForEntry did not recognize a single IP address")
+	}
+	if len(questions) != 1 {
+		t.Fatalf("got %d questions, want 1", len(questions))
+	}
+	if questions[0].forcedQtype != dns.TypePTR {
+		t.Errorf("forcedQtype = %d, want dns.TypePTR", questions[0].forcedQtype)
+	}
+	want, _ := dns.ReverseAddr("192.0.2.1")
+	if questions[0].name != want {
+		t.Errorf("name = %q, want %q", questions[0].name, want)
+	}
+}
+
+func TestPtrQuestionsForEntryCIDR(t *testing.T) {
+	questions, ok, err := ptrQuestionsForEntry("192.0.2.0/30")
+	if err != nil {
+		t.Fatalf("ptrQuestionsForEntry returned error: %v", err)
+	}
+	if !ok {
+		t.Fatal("ptrQuestionsForEntry did not recognize a CIDR range")
+	}
+	if len(questions) != 4 {
+		t.Fatalf("got %d questions, want 4", len(questions))
+	}
+	for _, q := range questions {
+		if q.forcedQtype != dns.TypePTR {
+			t.Errorf("forcedQtype = %d, want dns.TypePTR", q.forcedQtype)
+		}
+	}
+	want, _ := dns.ReverseAddr("192.0.2.3")
+	if questions[3].name != want {
+		t.Errorf("last question name = %q, want %q", questions[3].name, want)
+	}
+}
+
+func TestPtrQuestionsForEntryNotAnAddress(t *testing.T) {
+	_, ok, err := ptrQuestionsForEntry("example.com")
+	if err != nil {
+		t.Fatalf("ptrQuestionsForEntry returned error: %v", err)
+	}
+	if ok {
+		t.Fatal("ptrQuestionsForEntry treated a domain name as an IP/CIDR")
+	}
+}
+
+func TestPtrQuestionsForEntryCIDRTooLarge(t *testing.T) {
+	_, ok, err := ptrQuestionsForEntry("10.0.0.0/8")
+	if !ok {
+		t.Fatal("ptrQuestionsForEntry did not recognize an oversized CIDR range as a CIDR")
+	}
+	if err == nil {
+		t.Error("ptrQuestionsForEntry did not reject a CIDR range exceeding maxPTRCIDRExpansion")
+	}
+}
+
+func TestNextIP(t *testing.T) {
+	tests := []struct {
+		in   net.IP
+		want net.IP
+	}{
+		{net.ParseIP("192.0.2.1").To4(), net.ParseIP("192.0.2.2").To4()},
+		{net.ParseIP("192.0.2.255").To4(), net.ParseIP("192.0.3.0").To4()},
+		{net.ParseIP("255.255.255.255").To4(), net.ParseIP("0.0.0.0").To4()},
+	}
+	for _, tt := range tests {
+		got := nextIP(tt.in)
+		if !got.Equal(tt.want) {
+			t.Errorf("nextIP(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package dnsbench
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/miekg/dns"
+	"github.com/tantalor93/doh-go/doh"
+	"github.com/tantalor93/doq-go/doq"
+)
+
+// workerQueryFactory returns a function that each worker goroutine calls once to build its own
+// queryFunc for the resolved target b, picking the transport according to the flags set by
+// Benchmark.resolveServer. DoG and DNSCrypt reuse a connection/session across queries the same way
+// plain DNS and DoT already do, unless Benchmark.SeparateWorkerConnections is set.
+func workerQueryFactory(b *Benchmark) func() queryFunc {
+	switch {
+	case b.useGRPC:
+		return func() queryFunc {
+			query, err := grpcQueryFunc(b)
+			if err != nil {
+				return func(context.Context, *dns.Msg) (*dns.Msg, error) {
+					return nil, err
+				}
+			}
+			return query
+		}
+	case b.useDNSCrypt:
+		return func() queryFunc {
+			return dnscryptQueryFunc(b, b.dnscrypt)
+		}
+	case b.useDoH:
+		return func() queryFunc {
+			return dohQueryFunc(b)
+		}
+	case b.useQuic:
+		return func() queryFunc {
+			return quicQueryFunc(b)
+		}
+	default:
+		return func() queryFunc {
+			return classicQueryFunc(b)
+		}
+	}
+}
+
+// classicQueryFunc builds a queryFunc for plain DNS over UDP or TCP and for DoT, sharing a single
+// dns.Conn across queries (redialing on error or once Benchmark.QperConn is reached) unless
+// Benchmark.SeparateWorkerConnections forces a fresh connection for every query.
+func classicQueryFunc(b *Benchmark) queryFunc {
+	network := UDPTransport
+	if b.TCP {
+		network = TCPTransport
+	}
+	if b.DOT {
+		network = TLSTransport
+	}
+
+	dnsClient := &dns.Client{
+		Net:          network,
+		DialTimeout:  b.ConnectTimeout,
+		WriteTimeout: b.WriteTimeout,
+		ReadTimeout:  b.ReadTimeout,
+		Timeout:      b.RequestTimeout,
+		TLSConfig:    &tls.Config{InsecureSkipVerify: b.Insecure}, //nolint:gosec
+	}
+
+	var conn *dns.Conn
+	var queries int64
+	return func(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+		if conn != nil && (b.SeparateWorkerConnections || (b.QperConn > 0 && queries >= b.QperConn)) {
+			conn.Close()
+			conn = nil
+			queries = 0
+		}
+		if conn == nil {
+			c, err := dnsClient.DialContext(ctx, b.Server)
+			if err != nil {
+				return nil, err
+			}
+			conn = c
+		}
+		resp, _, err := dnsClient.ExchangeWithConnContext(ctx, req, conn)
+		queries++
+		if err != nil {
+			conn.Close()
+			conn = nil
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// dohQueryFunc builds a queryFunc that sends DNS queries over DNS-over-HTTPS, using the HTTP
+// method configured by Benchmark.DohMethod. Benchmark.DohProtocol is not applied here: the
+// http.Transport has a custom TLS config and no ForceAttemptHTTP2, so requests go over HTTP/1.1.
+func dohQueryFunc(b *Benchmark) queryFunc {
+	tr := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: b.Insecure}} //nolint:gosec
+	c := http.Client{Transport: tr, Timeout: b.ReadTimeout}
+	dohClient := doh.NewClient(b.Server, doh.WithHTTPClient(&c))
+
+	send := dohClient.SendViaPost
+	if b.DohMethod == GetHTTPMethod {
+		send = dohClient.SendViaGet
+	}
+
+	return func(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+		return send(ctx, req)
+	}
+}
+
+// quicQueryFunc builds a queryFunc that sends DNS queries over DNS-over-QUIC (DoQ), sharing a
+// single doq.Client, and therefore a single QUIC connection, across all queries issued by the worker.
+func quicQueryFunc(b *Benchmark) queryFunc {
+	quicClient := doq.NewClient(b.Server,
+		doq.WithTLSConfig(&tls.Config{InsecureSkipVerify: b.Insecure}), //nolint:gosec
+		doq.WithConnectTimeout(b.ConnectTimeout),
+		doq.WithWriteTimeout(b.WriteTimeout),
+		doq.WithReadTimeout(b.ReadTimeout),
+	)
+	return func(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+		return quicClient.Send(ctx, req)
+	}
+}
@@ -0,0 +1,143 @@
+package dnsbench
+
+import (
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Counters represents various counters of benchmark results.
+type Counters struct {
+	// Total is counter of all requests.
+	Total int64
+	// IOError is counter of all requests for which there was no answer.
+	IOError int64
+	// Success is counter of all responses which were successful (NOERROR, but not NODATA!).
+	Success int64
+	// Negative is counter of all responses which were negative (NODATA/NXDOMAIN).
+	Negative int64
+	// Error is counter of all responses which were not negative (NODATA/NXDOMAIN) or success (NOERROR response).
+	Error int64
+	// IDmismatch is counter of all responses which ID mismatched the request ID.
+	IDmismatch int64
+	// Truncated is counter of all responses which had truncated flag.
+	Truncated int64
+	// CacheColdQueries is counter of "cold" queries sent by Benchmark.CacheProbe, i.e. the first query
+	// of each cold/warm sequence. It counts queries only: whether a cold query was answered from the
+	// resolver's cache is not detected.
+	CacheColdQueries int64
+	// CacheWarmHits is counter of "warm" queries sent by Benchmark.CacheProbe that were heuristically
+	// detected as resolver cache hits, based on a decreased TTL compared to the preceding cold query.
+	CacheWarmHits int64
+}
+
+// Datapoint one datapoint of benchmark (single DNS request).
+type Datapoint struct {
+	Duration time.Duration
+	Start    time.Time
+}
+
+// ErrorDatapoint one datapoint representing single IO error of benchmark.
+type ErrorDatapoint struct {
+	Start time.Time
+	Err   error
+}
+
+// ResultStats is a representation of benchmark results of single concurrent thread, scoped to a
+// single resolved target server.
+type ResultStats struct {
+	// Server is the resolved target server these results were collected against, matching
+	// Benchmark.Server (or one entry of Benchmark.Servers when more than one server is benchmarked).
+	Server  string
+	Codes   map[int]int64
+	Qtypes  map[string]int64
+	Timings []Datapoint
+	// CacheColdTimings holds the durations of the "cold" queries sent by Benchmark.CacheProbe, kept
+	// separate from Timings so cold and warm latencies can be reported (e.g. p50/p99) independently.
+	CacheColdTimings []Datapoint
+	// CacheWarmTimings holds the durations of the "warm" queries sent by Benchmark.CacheProbe,
+	// regardless of whether they were detected as cache hits, kept separate from Timings for the
+	// same reason as CacheColdTimings.
+	CacheWarmTimings []Datapoint
+	Counters         *Counters
+	Errors           []ErrorDatapoint
+}
+
+func newResultStats(b *Benchmark, server string) *ResultStats {
+	st := &ResultStats{Server: server, Counters: &Counters{}}
+	if b.Rcodes {
+		st.Codes = make(map[int]int64)
+	}
+	st.Qtypes = make(map[string]int64)
+	return st
+}
+
+// record accounts a single completed DNS request/response pair (or the error from attempting one)
+// into the result stats.
+func (r *ResultStats) record(req *dns.Msg, resp *dns.Msg, err error, start time.Time, duration time.Duration) {
+	r.Counters.Total++
+
+	if err != nil {
+		r.Counters.IOError++
+		r.Errors = append(r.Errors, ErrorDatapoint{Start: start, Err: err})
+		return
+	}
+
+	r.Timings = append(r.Timings, Datapoint{Duration: duration, Start: start})
+	r.Qtypes[dns.TypeToString[req.Question[0].Qtype]]++
+
+	if r.Codes != nil {
+		r.Codes[resp.Rcode]++
+	}
+
+	if resp.Truncated {
+		r.Counters.Truncated++
+	}
+
+	if resp.Id != req.Id {
+		r.Counters.IDmismatch++
+		return
+	}
+
+	switch resp.Rcode {
+	case dns.RcodeSuccess:
+		if len(resp.Answer) == 0 {
+			r.Counters.Negative++
+		} else {
+			r.Counters.Success++
+		}
+	case dns.RcodeNameError:
+		r.Counters.Negative++
+	default:
+		r.Counters.Error++
+	}
+}
+
+// GroupResultStatsByServer groups the flat []*ResultStats returned by Benchmark.Run (one entry per
+// worker/server pair) by their Server field, so that per-server report/JSON/plot output can be built
+// on top of it without each consumer re-implementing the grouping. The entries of each server keep
+// their order from stats; the returned map itself does not keep any order of servers.
+func GroupResultStatsByServer(stats []*ResultStats) map[string][]*ResultStats {
+	grouped := make(map[string][]*ResultStats)
+	for _, st := range stats {
+		grouped[st.Server] = append(grouped[st.Server], st)
+	}
+	return grouped
+}
+
+// recordCacheProbe accounts a single query fired by Benchmark.CacheProbe. warm is false for the
+// initial "cold" query of each sequence and true for the following "warm" queries, for which hit
+// additionally reports whether the query was heuristically detected as a resolver cache hit. The
+// cold and warm durations are kept in separate Datapoint slices (CacheColdTimings/CacheWarmTimings)
+// so their latency distributions don't get blended together with each other or with Timings.
+func (r *ResultStats) recordCacheProbe(warm bool, hit bool, start time.Time, duration time.Duration) {
+	if !warm {
+		r.Counters.CacheColdQueries++
+		r.CacheColdTimings = append(r.CacheColdTimings, Datapoint{Duration: duration, Start: start})
+		return
+	}
+	r.CacheWarmTimings = append(r.CacheWarmTimings, Datapoint{Duration: duration, Start: start})
+	if hit {
+		r.Counters.CacheWarmHits++
+	}
+}
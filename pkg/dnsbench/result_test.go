@@ -0,0 +1,60 @@
+package dnsbench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordCacheProbeSeparatesColdAndWarmTimings(t *testing.T) {
+	st := newResultStats(&Benchmark{}, "192.0.2.1:53")
+
+	st.recordCacheProbe(false, false, time.Now(), 100*time.Millisecond)
+	st.recordCacheProbe(true, true, time.Now(), 10*time.Millisecond)
+	st.recordCacheProbe(true, false, time.Now(), 90*time.Millisecond)
+
+	if got := st.Counters.CacheColdQueries; got != 1 {
+		t.Errorf("CacheColdQueries = %d, want 1", got)
+	}
+	if got := st.Counters.CacheWarmHits; got != 1 {
+		t.Errorf("CacheWarmHits = %d, want 1", got)
+	}
+
+	if len(st.CacheColdTimings) != 1 || st.CacheColdTimings[0].Duration != 100*time.Millisecond {
+		t.Errorf("CacheColdTimings = %v, want a single 100ms datapoint", st.CacheColdTimings)
+	}
+	if len(st.CacheWarmTimings) != 2 {
+		t.Fatalf("CacheWarmTimings = %v, want 2 datapoints", st.CacheWarmTimings)
+	}
+	if st.CacheWarmTimings[0].Duration != 10*time.Millisecond || st.CacheWarmTimings[1].Duration != 90*time.Millisecond {
+		t.Errorf("CacheWarmTimings durations = %v, %v, want 10ms, 90ms", st.CacheWarmTimings[0].Duration, st.CacheWarmTimings[1].Duration)
+	}
+
+	// cache-probe datapoints must not leak into the ordinary Timings bucket.
+	if len(st.Timings) != 0 {
+		t.Errorf("Timings = %v, want empty (cache-probe queries are not ordinary queries)", st.Timings)
+	}
+}
+
+func TestFirstAnswerTTL(t *testing.T) {
+	if ttl, ok := firstAnswerTTL(nil); ok || ttl != 0 {
+		t.Errorf("firstAnswerTTL(nil) = (%d, %v), want (0, false)", ttl, ok)
+	}
+}
+
+func TestGroupResultStatsByServer(t *testing.T) {
+	a1 := newResultStats(&Benchmark{}, "a")
+	b1 := newResultStats(&Benchmark{}, "b")
+	a2 := newResultStats(&Benchmark{}, "a")
+
+	grouped := GroupResultStatsByServer([]*ResultStats{a1, b1, a2})
+
+	if len(grouped) != 2 {
+		t.Fatalf("got %d servers, want 2", len(grouped))
+	}
+	if got := grouped["a"]; len(got) != 2 || got[0] != a1 || got[1] != a2 {
+		t.Errorf(`grouped["a"] = %v, want [a1, a2]`, got)
+	}
+	if got := grouped["b"]; len(got) != 1 || got[0] != b1 {
+		t.Errorf(`grouped["b"] = %v, want [b1]`, got)
+	}
+}
@@ -0,0 +1,51 @@
+package dnsbench
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func threeResolvedServers() []*Benchmark {
+	return []*Benchmark{{Server: "a"}, {Server: "b"}, {Server: "c"}}
+}
+
+func TestPickServerIndexRoundRobin(t *testing.T) {
+	b := &Benchmark{ServerStrategy: RoundRobinStrategy, resolvedServers: threeResolvedServers()}
+	var counter uint32
+	rando := rand.New(rand.NewSource(1))
+
+	want := []int{0, 1, 2, 0, 1}
+	for i, w := range want {
+		if got := b.pickServerIndex(0, &counter, rando); got != w {
+			t.Errorf("call %d: pickServerIndex() = %d, want %d", i, got, w)
+		}
+	}
+}
+
+func TestPickServerIndexStickyWorker(t *testing.T) {
+	b := &Benchmark{ServerStrategy: StickyWorkerStrategy, resolvedServers: threeResolvedServers()}
+	var counter uint32
+	rando := rand.New(rand.NewSource(1))
+
+	for _, workerID := range []uint32{0, 1, 2, 3, 4} {
+		want := int(workerID) % len(b.resolvedServers)
+		for i := 0; i < 3; i++ {
+			if got := b.pickServerIndex(workerID, &counter, rando); got != want {
+				t.Errorf("workerID %d call %d: pickServerIndex() = %d, want %d", workerID, i, got, want)
+			}
+		}
+	}
+}
+
+func TestPickServerIndexRandom(t *testing.T) {
+	b := &Benchmark{ServerStrategy: RandomStrategy, resolvedServers: threeResolvedServers()}
+	var counter uint32
+	rando := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		got := b.pickServerIndex(0, &counter, rando)
+		if got < 0 || got >= len(b.resolvedServers) {
+			t.Fatalf("pickServerIndex() = %d, want value in [0, %d)", got, len(b.resolvedServers))
+		}
+	}
+}